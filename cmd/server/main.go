@@ -1,10 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/stebennett/notification-server/internal/config"
+	"github.com/stebennett/notification-server/internal/metrics"
+	"github.com/stebennett/notification-server/internal/queue"
+	"github.com/stebennett/notification-server/internal/server"
 )
 
 func main() {
@@ -13,10 +23,48 @@ func main() {
 		log.Fatalf("failed to load configuration: %v", err)
 	}
 
-	log.Printf("configuration loaded successfully")
-	log.Printf("server port: %d", cfg.ServerPort)
-	log.Printf("log level: %s", cfg.LogLevel)
-	log.Printf("rabbitmq prefetch: %d", cfg.RabbitMQPrefetch)
+	logger := slog.Default()
+	logger.Info("configuration loaded successfully",
+		"server_port", cfg.ServerPort,
+		"log_level", cfg.LogLevel,
+		"rabbitmq_prefetch", cfg.RabbitMQPrefetch,
+	)
 
-	os.Exit(0)
+	tlsConfig, err := cfg.RabbitMQTLSConfig()
+	if err != nil {
+		log.Fatalf("failed to build rabbitmq tls config: %v", err)
+	}
+
+	conn := queue.NewConnection(cfg.RabbitMQURL, logger,
+		queue.WithTLSConfig(tlsConfig),
+		queue.WithHeartbeat(cfg.RabbitMQHeartbeat),
+		queue.WithConnectionName(cfg.RabbitMQConnectionName),
+		queue.WithDialTimeout(cfg.RabbitMQDialTimeout),
+	)
+	metrics.Hook(conn)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := conn.Connect(ctx); err != nil {
+		log.Fatalf("failed to connect to rabbitmq: %v", err)
+	}
+	defer conn.Close()
+
+	httpServer := server.New(fmt.Sprintf(":%d", cfg.ServerPort), conn)
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("http server failed", "error", err)
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("http server shutdown failed", "error", err)
+	}
 }
@@ -15,6 +15,17 @@ type Config struct {
 	RabbitMQURL      string `env:"RABBITMQ_URL,required"`
 	RabbitMQPrefetch int    `env:"RABBITMQ_PREFETCH" envDefault:"10"`
 
+	// RabbitMQ TLS
+	RabbitMQTLSCA                 string `env:"RABBITMQ_TLS_CA"`
+	RabbitMQTLSCert               string `env:"RABBITMQ_TLS_CERT"`
+	RabbitMQTLSKey                string `env:"RABBITMQ_TLS_KEY"`
+	RabbitMQTLSInsecureSkipVerify bool   `env:"RABBITMQ_TLS_INSECURE_SKIP_VERIFY" envDefault:"false"`
+
+	// RabbitMQ connection tuning
+	RabbitMQHeartbeat      time.Duration `env:"RABBITMQ_HEARTBEAT" envDefault:"10s"`
+	RabbitMQConnectionName string        `env:"RABBITMQ_CONNECTION_NAME" envDefault:"notification-server"`
+	RabbitMQDialTimeout    time.Duration `env:"RABBITMQ_DIAL_TIMEOUT" envDefault:"30s"`
+
 	// Retry
 	RetryMaxAttempts       int           `env:"RETRY_MAX_ATTEMPTS" envDefault:"3"`
 	RetryInitialDelay      time.Duration `env:"RETRY_INITIAL_DELAY" envDefault:"5s"`
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// RabbitMQTLSConfig builds a *tls.Config from the configured CA/cert/key
+// files, or returns (nil, nil) when none are set and InsecureSkipVerify is
+// off, so callers can fall back to a plain (non-TLS) dial.
+func (c *Config) RabbitMQTLSConfig() (*tls.Config, error) {
+	if c.RabbitMQTLSCA == "" && c.RabbitMQTLSCert == "" && c.RabbitMQTLSKey == "" && !c.RabbitMQTLSInsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.RabbitMQTLSInsecureSkipVerify,
+	}
+
+	if c.RabbitMQTLSCA != "" {
+		caCert, err := os.ReadFile(c.RabbitMQTLSCA)
+		if err != nil {
+			return nil, fmt.Errorf("read rabbitmq tls ca: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("parse rabbitmq tls ca: no certificates found")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.RabbitMQTLSCert != "" || c.RabbitMQTLSKey != "" {
+		cert, err := tls.LoadX509KeyPair(c.RabbitMQTLSCert, c.RabbitMQTLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("load rabbitmq tls client cert: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
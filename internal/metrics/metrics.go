@@ -0,0 +1,88 @@
+// Package metrics instruments the queue package's connection, publisher and
+// consumer behaviour for Prometheus, without the queue package itself
+// needing to depend on prometheus.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/stebennett/notification-server/internal/queue"
+)
+
+var (
+	ReconnectAttemptsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rabbitmq_reconnect_attempts_total",
+		Help: "Total number of RabbitMQ reconnect attempts, including failures.",
+	})
+
+	ReconnectsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rabbitmq_reconnects_total",
+		Help: "Total number of successful RabbitMQ reconnections.",
+	})
+
+	PublishTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_publish_total",
+		Help: "Total number of publish calls by final result, after retries.",
+	}, []string{"result"})
+
+	ConsumeTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_consume_total",
+		Help: "Total number of consumer runs by result.",
+	}, []string{"result"})
+
+	PublishLatencySeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "rabbitmq_publish_latency_seconds",
+		Help:    "Latency of publish calls, including retries.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	ReconnectDelaySeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "rabbitmq_reconnect_delay_seconds",
+		Help:    "Backoff delay waited before each reconnect attempt.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
+// Hook wires conn's reconnect and consume callbacks into the package's
+// counters and histograms. Call it once after constructing the Connection
+// and before Connect.
+func Hook(conn *queue.Connection) {
+	conn.OnReconnectAttempt = func(delay time.Duration) {
+		ReconnectAttemptsTotal.Inc()
+		ReconnectDelaySeconds.Observe(delay.Seconds())
+	}
+	conn.OnReconnect = func() {
+		ReconnectsTotal.Inc()
+	}
+	conn.OnConsume = ObserveConsume
+}
+
+// HookPublisher wires pub's publish callback into the package's counters
+// and histogram. Call it once after constructing the Publisher.
+func HookPublisher(pub *queue.Publisher) {
+	pub.OnPublish = ObservePublish
+}
+
+// ObservePublish records the outcome of a single Publisher.Publish call.
+// Publisher calls this itself via OnPublish once HookPublisher has wired
+// it up.
+func ObservePublish(err error, duration time.Duration) {
+	PublishTotal.WithLabelValues(resultLabel(err)).Inc()
+	PublishLatencySeconds.Observe(duration.Seconds())
+}
+
+// ObserveConsume records the outcome of a Consumer.Consume call ending.
+// Connection calls this itself via OnConsume once Hook has wired it up.
+func ObserveConsume(err error) {
+	ConsumeTotal.WithLabelValues(resultLabel(err)).Inc()
+}
+
+func resultLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
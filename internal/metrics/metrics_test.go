@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/stebennett/notification-server/internal/queue"
+)
+
+func TestResultLabel(t *testing.T) {
+	if got := resultLabel(nil); got != "success" {
+		t.Errorf("expected success, got %q", got)
+	}
+	if got := resultLabel(errors.New("boom")); got != "error" {
+		t.Errorf("expected error, got %q", got)
+	}
+}
+
+func TestHook(t *testing.T) {
+	conn := queue.NewConnection("amqp://localhost:5672/", nil)
+	Hook(conn)
+
+	if conn.OnReconnectAttempt == nil {
+		t.Fatal("expected OnReconnectAttempt to be set")
+	}
+	if conn.OnReconnect == nil {
+		t.Fatal("expected OnReconnect to be set")
+	}
+	if conn.OnConsume == nil {
+		t.Fatal("expected OnConsume to be set")
+	}
+
+	before := testutil.ToFloat64(ReconnectAttemptsTotal)
+	conn.OnReconnectAttempt(100 * time.Millisecond)
+	if after := testutil.ToFloat64(ReconnectAttemptsTotal); after != before+1 {
+		t.Errorf("expected ReconnectAttemptsTotal to increment, got %v -> %v", before, after)
+	}
+
+	before = testutil.ToFloat64(ReconnectsTotal)
+	conn.OnReconnect()
+	if after := testutil.ToFloat64(ReconnectsTotal); after != before+1 {
+		t.Errorf("expected ReconnectsTotal to increment, got %v -> %v", before, after)
+	}
+
+	before = testutil.ToFloat64(ConsumeTotal.WithLabelValues("error"))
+	conn.OnConsume(errors.New("boom"))
+	if after := testutil.ToFloat64(ConsumeTotal.WithLabelValues("error")); after != before+1 {
+		t.Errorf("expected ConsumeTotal{result=error} to increment, got %v -> %v", before, after)
+	}
+}
+
+func TestHookPublisher(t *testing.T) {
+	pub := &queue.Publisher{}
+	HookPublisher(pub)
+
+	if pub.OnPublish == nil {
+		t.Fatal("expected OnPublish to be set")
+	}
+
+	beforeTotal := testutil.ToFloat64(PublishTotal.WithLabelValues("success"))
+	beforeCount := histogramSampleCount(t, PublishLatencySeconds)
+	pub.OnPublish(nil, 50*time.Millisecond)
+
+	if after := testutil.ToFloat64(PublishTotal.WithLabelValues("success")); after != beforeTotal+1 {
+		t.Errorf("expected PublishTotal{result=success} to increment, got %v -> %v", beforeTotal, after)
+	}
+	if after := histogramSampleCount(t, PublishLatencySeconds); after != beforeCount+1 {
+		t.Errorf("expected PublishLatencySeconds observation count to increment, got %v -> %v", beforeCount, after)
+	}
+}
+
+// histogramSampleCount returns the total number of observations recorded by
+// h, since testutil.ToFloat64 only supports single-value metrics.
+func histogramSampleCount(t *testing.T, h prometheus.Histogram) uint64 {
+	t.Helper()
+
+	var m dto.Metric
+	if err := h.Write(&m); err != nil {
+		t.Fatalf("write histogram: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
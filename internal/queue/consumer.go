@@ -0,0 +1,129 @@
+package queue
+
+import (
+	"context"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Consumer declares its topology and consumes deliveries on a channel handed
+// to it by a Connection. Both methods are re-invoked every time the
+// underlying connection is re-established, so Declare must be idempotent and
+// Consume must return once its channel or context is done rather than
+// assuming it owns the connection for the lifetime of the process.
+type Consumer interface {
+	// Declare sets up any exchanges, queues and bindings the consumer needs.
+	Declare(ctx context.Context, ch *amqp.Channel) error
+
+	// Consume runs the consume loop and blocks until ch or ctx is done, or
+	// an unrecoverable error occurs.
+	Consume(ctx context.Context, ch *amqp.Channel) error
+}
+
+// StartConsumer runs cons in its own goroutine, opening a dedicated channel
+// for it from the connection's channel pool. If the connection is lost,
+// StartConsumer waits for a reconnect signal and re-runs Declare and Consume
+// on a fresh channel, rather than leaving cons holding a channel that was
+// silently swapped out from under it.
+func (c *Connection) StartConsumer(ctx context.Context, name string, cons Consumer) {
+	go c.runConsumer(ctx, name, cons)
+}
+
+func (c *Connection) runConsumer(ctx context.Context, name string, cons Consumer) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.done:
+			return
+		default:
+		}
+
+		ch, err := c.Channels().Acquire()
+		if err != nil {
+			c.logger.Warn("consumer waiting for connection", "consumer", name, "error", err)
+			c.setConsumerHealthy(name, false)
+			if !c.waitForReconnect(ctx) {
+				return
+			}
+			continue
+		}
+
+		if err := cons.Declare(ctx, ch); err != nil {
+			c.logger.Error("consumer declare failed", "consumer", name, "error", err)
+			c.setConsumerHealthy(name, false)
+			ch.Close()
+			if !c.waitForReconnect(ctx) {
+				return
+			}
+			continue
+		}
+
+		c.setConsumerHealthy(name, true)
+		err = cons.Consume(ctx, ch)
+		c.setConsumerHealthy(name, false)
+		if c.OnConsume != nil {
+			c.OnConsume(err)
+		}
+		if err != nil {
+			c.logger.Warn("consumer stopped", "consumer", name, "error", err)
+		}
+		ch.Close()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.done:
+			return
+		default:
+		}
+
+		if !c.waitForReconnect(ctx) {
+			return
+		}
+	}
+}
+
+// waitForReconnect blocks until the connection is re-established, the
+// context is cancelled or the connection is closed. It returns false when
+// the caller should stop retrying.
+func (c *Connection) waitForReconnect(ctx context.Context) bool {
+	reconnected, cancel := c.NotifyReconnect()
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-c.done:
+		return false
+	case <-reconnected:
+		return true
+	}
+}
+
+// setConsumerHealthy records whether the named consumer currently has an
+// active channel, for ConsumersHealthy to report on.
+func (c *Connection) setConsumerHealthy(name string, healthy bool) {
+	c.consumersMu.Lock()
+	defer c.consumersMu.Unlock()
+
+	if c.consumers == nil {
+		c.consumers = make(map[string]bool)
+	}
+	c.consumers[name] = healthy
+}
+
+// ConsumersHealthy reports whether every consumer started via StartConsumer
+// currently has an active channel. It returns true when no consumers have
+// been started.
+func (c *Connection) ConsumersHealthy() bool {
+	c.consumersMu.RLock()
+	defer c.consumersMu.RUnlock()
+
+	for _, healthy := range c.consumers {
+		if !healthy {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// neverCalledConsumer fails the test if Declare or Consume is ever invoked.
+// It's used to drive runConsumer's disconnected/reconnect-wait branch
+// without needing a real broker.
+type neverCalledConsumer struct {
+	t *testing.T
+}
+
+func (c *neverCalledConsumer) Declare(ctx context.Context, ch *amqp.Channel) error {
+	c.t.Fatal("Declare should not be called while the connection has never connected")
+	return nil
+}
+
+func (c *neverCalledConsumer) Consume(ctx context.Context, ch *amqp.Channel) error {
+	c.t.Fatal("Consume should not be called while the connection has never connected")
+	return nil
+}
+
+func TestConnection_WaitForReconnect(t *testing.T) {
+	t.Run("returns true once notified", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil)
+
+		done := make(chan bool, 1)
+		go func() {
+			done <- conn.waitForReconnect(context.Background())
+		}()
+
+		// Give waitForReconnect a chance to subscribe before notifying.
+		time.Sleep(10 * time.Millisecond)
+		conn.notifyReconnected()
+
+		select {
+		case ok := <-done:
+			if !ok {
+				t.Error("expected waitForReconnect to return true")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("waitForReconnect did not return after notifyReconnected")
+		}
+	})
+
+	t.Run("returns false when the context is cancelled", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan bool, 1)
+		go func() {
+			done <- conn.waitForReconnect(ctx)
+		}()
+
+		cancel()
+
+		select {
+		case ok := <-done:
+			if ok {
+				t.Error("expected waitForReconnect to return false")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("waitForReconnect did not return after context cancellation")
+		}
+	})
+
+	t.Run("returns false when the connection is closed", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil)
+
+		done := make(chan bool, 1)
+		go func() {
+			done <- conn.waitForReconnect(context.Background())
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		conn.Close()
+
+		select {
+		case ok := <-done:
+			if ok {
+				t.Error("expected waitForReconnect to return false")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("waitForReconnect did not return after Close")
+		}
+	})
+
+	t.Run("unsubscribes so reconnectSubs does not grow across retries", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel() // already cancelled: each call returns immediately
+
+		for i := 0; i < 5; i++ {
+			conn.waitForReconnect(ctx)
+		}
+
+		if got := len(conn.reconnectSubs); got != 0 {
+			t.Errorf("expected reconnectSubs to be empty after repeated waitForReconnect calls, got %d entries", got)
+		}
+	})
+}
+
+func TestConnection_StartConsumer(t *testing.T) {
+	t.Run("marks the consumer unhealthy and keeps retrying until the context is cancelled", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan struct{})
+
+		go func() {
+			conn.runConsumer(ctx, "test", &neverCalledConsumer{t: t})
+			close(stopped)
+		}()
+
+		// The connection never connects, so Acquire keeps failing with
+		// ErrNotConnected and the consumer is reported unhealthy.
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			if !conn.ConsumersHealthy() {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if conn.ConsumersHealthy() {
+			t.Error("expected the consumer to be reported unhealthy while disconnected")
+		}
+
+		// A reconnect signal (as a successful Connect or reconnect would
+		// send) must wake the loop rather than leaving it blocked forever.
+		conn.notifyReconnected()
+
+		cancel()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("runConsumer did not return after context cancellation")
+		}
+	})
+}
+
+func TestConnection_ConsumersHealthy(t *testing.T) {
+	t.Run("is true when no consumers have been started", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil)
+
+		if !conn.ConsumersHealthy() {
+			t.Error("expected true when no consumers are registered")
+		}
+	})
+
+	t.Run("is false if any consumer is unhealthy", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil)
+
+		conn.setConsumerHealthy("a", true)
+		conn.setConsumerHealthy("b", false)
+
+		if conn.ConsumersHealthy() {
+			t.Error("expected false when a consumer is unhealthy")
+		}
+	})
+
+	t.Run("is true when every consumer is healthy", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil)
+
+		conn.setConsumerHealthy("a", true)
+		conn.setConsumerHealthy("b", true)
+
+		if !conn.ConsumersHealthy() {
+			t.Error("expected true when every consumer is healthy")
+		}
+	})
+}
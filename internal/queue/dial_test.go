@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestConnection_DialFunc(t *testing.T) {
+	t.Run("Connect uses the injected DialFunc", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil)
+
+		var gotURL string
+		var gotCfg amqp.Config
+		wantErr := errors.New("fake dial error")
+
+		conn.DialFunc = func(url string, cfg amqp.Config) (*amqp.Connection, error) {
+			gotURL = url
+			gotCfg = cfg
+			return nil, wantErr
+		}
+
+		err := conn.Connect(context.Background())
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("expected %v, got %v", wantErr, err)
+		}
+		if gotURL != conn.urls[0] {
+			t.Errorf("expected url %q, got %q", conn.urls[0], gotURL)
+		}
+		if gotCfg.Heartbeat != 10*time.Second {
+			t.Errorf("expected default heartbeat 10s, got %v", gotCfg.Heartbeat)
+		}
+	})
+}
+
+func TestConnection_Connect_NotifiesWaitingSubscribers(t *testing.T) {
+	t.Run("a successful Connect wakes a consumer already blocked in waitForReconnect", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		conn.DialFunc = func(url string, cfg amqp.Config) (*amqp.Connection, error) {
+			return &amqp.Connection{}, nil
+		}
+
+		sub, subCancel := conn.NotifyReconnect()
+		defer subCancel()
+
+		if err := conn.Connect(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		select {
+		case <-sub:
+		default:
+			t.Error("expected the initial Connect to notify NotifyReconnect subscribers, the same as a later reconnect would")
+		}
+	})
+}
+
+func TestConnection_Options(t *testing.T) {
+	t.Run("WithTLSConfig sets the client TLS config", func(t *testing.T) {
+		tlsConfig := &tls.Config{InsecureSkipVerify: true}
+		conn := NewConnection("amqp://localhost:5672/", nil, WithTLSConfig(tlsConfig))
+
+		if conn.amqpConfig.TLSClientConfig != tlsConfig {
+			t.Error("expected TLS config to be set")
+		}
+	})
+
+	t.Run("WithHeartbeat overrides the default heartbeat", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil, WithHeartbeat(5*time.Second))
+
+		if conn.amqpConfig.Heartbeat != 5*time.Second {
+			t.Errorf("expected heartbeat 5s, got %v", conn.amqpConfig.Heartbeat)
+		}
+	})
+
+	t.Run("WithConnectionName sets the connection_name property", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil, WithConnectionName("my-service"))
+
+		if conn.amqpConfig.Properties["connection_name"] != "my-service" {
+			t.Errorf("expected connection_name %q, got %v", "my-service", conn.amqpConfig.Properties["connection_name"])
+		}
+	})
+
+	t.Run("WithDialTimeout sets a custom dialer", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil, WithDialTimeout(2*time.Second))
+
+		if conn.amqpConfig.Dial == nil {
+			t.Error("expected a custom dial function to be set")
+		}
+	})
+}
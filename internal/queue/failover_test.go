@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestConnection_MultiURL(t *testing.T) {
+	t.Run("parses a comma-separated URL list", func(t *testing.T) {
+		conn := NewConnection("amqp://a/,amqp://b/ , amqp://c/", nil)
+
+		want := []string{"amqp://a/", "amqp://b/", "amqp://c/"}
+		if len(conn.urls) != len(want) {
+			t.Fatalf("expected %v, got %v", want, conn.urls)
+		}
+		for i, u := range want {
+			if conn.urls[i] != u {
+				t.Errorf("index %d: expected %q, got %q", i, u, conn.urls[i])
+			}
+		}
+	})
+
+	t.Run("WithURLs overrides the parsed list", func(t *testing.T) {
+		conn := NewConnection("amqp://a/", nil, WithURLs("amqp://x/", "amqp://y/"))
+
+		want := []string{"amqp://x/", "amqp://y/"}
+		if len(conn.urls) != len(want) {
+			t.Fatalf("expected %v, got %v", want, conn.urls)
+		}
+	})
+
+	t.Run("dialRoundRobin fails over to the next URL on the first attempt", func(t *testing.T) {
+		conn := NewConnection("amqp://a/,amqp://b/", nil)
+
+		var dialed []string
+		conn.DialFunc = func(url string, cfg amqp.Config) (*amqp.Connection, error) {
+			dialed = append(dialed, url)
+			if url == "amqp://a/" {
+				return nil, errors.New("refused")
+			}
+			return nil, nil
+		}
+
+		_, idx, err := conn.dialRoundRobin(context.Background(), 0)
+		if err != nil {
+			t.Fatalf("expected failover to succeed, got %v", err)
+		}
+		if idx != 1 {
+			t.Errorf("expected to succeed on index 1, got %d", idx)
+		}
+		if len(dialed) != 2 || dialed[0] != "amqp://a/" || dialed[1] != "amqp://b/" {
+			t.Errorf("expected both URLs to be tried in order, got %v", dialed)
+		}
+	})
+
+	t.Run("dialRoundRobin starts from the given index and wraps", func(t *testing.T) {
+		conn := NewConnection("amqp://a/,amqp://b/,amqp://c/", nil)
+
+		var dialed []string
+		conn.DialFunc = func(url string, cfg amqp.Config) (*amqp.Connection, error) {
+			dialed = append(dialed, url)
+			if url == "amqp://a/" {
+				return nil, nil
+			}
+			return nil, errors.New("refused")
+		}
+
+		_, idx, err := conn.dialRoundRobin(context.Background(), 2)
+		if err != nil {
+			t.Fatalf("expected failover to succeed, got %v", err)
+		}
+		if idx != 0 {
+			t.Errorf("expected to wrap to index 0, got %d", idx)
+		}
+		want := []string{"amqp://c/", "amqp://a/"}
+		if len(dialed) != len(want) || dialed[0] != want[0] || dialed[1] != want[1] {
+			t.Errorf("expected %v, got %v", want, dialed)
+		}
+	})
+
+	t.Run("Connect returns ErrNoURLs when the list is empty after trimming", func(t *testing.T) {
+		conn := NewConnection("  ,  ", nil)
+
+		err := conn.Connect(context.Background())
+		if !errors.Is(err, ErrNoURLs) {
+			t.Errorf("expected ErrNoURLs, got %v", err)
+		}
+	})
+
+	t.Run("Connect returns the last error when every URL fails", func(t *testing.T) {
+		conn := NewConnection("amqp://a/,amqp://b/", nil)
+
+		wantErr := errors.New("refused")
+		conn.DialFunc = func(url string, cfg amqp.Config) (*amqp.Connection, error) {
+			return nil, wantErr
+		}
+
+		err := conn.Connect(context.Background())
+		if !errors.Is(err, wantErr) {
+			t.Errorf("expected %v, got %v", wantErr, err)
+		}
+	})
+}
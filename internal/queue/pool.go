@@ -0,0 +1,29 @@
+package queue
+
+import (
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ChannelPool hands out channels on top of a single Connection. RabbitMQ
+// recommends a channel per publisher and per consumer rather than sharing
+// one across goroutines, so the pool exists to make that the easy default
+// instead of something callers have to remember.
+type ChannelPool struct {
+	conn *Connection
+}
+
+// NewChannelPool creates a ChannelPool backed by conn.
+func NewChannelPool(conn *Connection) *ChannelPool {
+	return &ChannelPool{conn: conn}
+}
+
+// Acquire returns a new channel for exclusive use by a single publisher or
+// consumer. It returns ErrNotConnected or ErrClosed if conn isn't usable.
+func (p *ChannelPool) Acquire() (*amqp.Channel, error) {
+	return p.conn.Channel()
+}
+
+// Channels returns the connection's default channel pool.
+func (c *Connection) Channels() *ChannelPool {
+	return NewChannelPool(c)
+}
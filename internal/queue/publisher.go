@@ -0,0 +1,233 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ErrPublishRejected is returned when a publish is nacked or returned by the
+// broker after exhausting all retry attempts.
+var ErrPublishRejected = errors.New("publish rejected by broker")
+
+// RetryConfig controls the backoff used when a publish needs to be retried.
+// Its fields mirror config.Config's Retry* settings one for one, so callers
+// can copy those settings across field by field.
+type RetryConfig struct {
+	MaxAttempts       int
+	InitialDelay      time.Duration
+	MaxDelay          time.Duration
+	BackoffMultiplier float64
+	JitterFactor      float64
+}
+
+// publisherChannel is the subset of *amqp.Channel the Publisher needs,
+// extracted so tests can inject a fake instead of dialing a real broker.
+type publisherChannel interface {
+	Confirm(noWait bool) error
+	NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation
+	NotifyReturn(c chan amqp.Return) chan amqp.Return
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	Close() error
+}
+
+// Publisher publishes messages over a single channel opened in publisher
+// confirms mode, retrying nacked, returned or timed-out publishes with
+// exponential backoff. The channel is opened once and reused across calls
+// and retries, rather than paying a fresh channel.open/confirm.select round
+// trip per publish; it is only torn down and reopened after an error that
+// might have left it unusable or after a returned message. Publish is safe for concurrent use: each
+// publish-and-wait-for-confirm attempt runs under mu for its whole duration,
+// since the shared channel has no way to correlate a confirm or return back
+// to the publish that caused it (no delivery-tag tracking), so attempts must
+// not interleave.
+type Publisher struct {
+	conn    *Connection
+	retry   RetryConfig
+	logger  *slog.Logger
+	confirm time.Duration
+
+	// newChannel opens and configures a fresh confirm-mode channel.
+	// Overridable in tests.
+	newChannel func() (publisherChannel, <-chan amqp.Confirmation, <-chan amqp.Return, error)
+
+	// mu serializes publishOnce attempts; see the Publisher doc comment.
+	mu       sync.Mutex
+	ch       publisherChannel
+	confirms <-chan amqp.Confirmation
+	returns  <-chan amqp.Return
+
+	// OnPublish, if set, is called after every Publish call (including all
+	// of its retries) with the final error and total elapsed time. It
+	// exists so a metrics package can observe publish outcomes without
+	// Publisher depending on prometheus.
+	OnPublish func(err error, d time.Duration)
+}
+
+// NewPublisher creates a Publisher backed by conn, retrying according to
+// retry. confirmTimeout bounds how long a single publish attempt waits for
+// the broker's confirm before it is treated as failed and retried.
+func NewPublisher(conn *Connection, retry RetryConfig, confirmTimeout time.Duration, logger *slog.Logger) *Publisher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	p := &Publisher{
+		conn:    conn,
+		retry:   retry,
+		logger:  logger,
+		confirm: confirmTimeout,
+	}
+	p.newChannel = p.openChannel
+	return p
+}
+
+// Publish sends msg to exchange with routing key, retrying on failure with
+// exponential backoff and jitter until retry.MaxAttempts is reached or ctx is
+// cancelled. A shutdown bounded by ctx's deadline will not block forever
+// waiting on a confirm.
+func (p *Publisher) Publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	start := time.Now()
+	err := p.publishWithRetry(ctx, exchange, key, msg)
+
+	if p.OnPublish != nil {
+		p.OnPublish(err, time.Since(start))
+	}
+
+	return err
+}
+
+func (p *Publisher) publishWithRetry(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	var lastErr error
+
+	for attempt := 0; attempt < p.retry.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := p.backoff(attempt)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		err := p.publishOnce(ctx, exchange, key, msg)
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+		p.logger.Warn("publish attempt failed", "attempt", attempt+1, "error", err)
+	}
+
+	return fmt.Errorf("publish failed after %d attempts: %w", p.retry.MaxAttempts, lastErr)
+}
+
+// openChannel opens a new channel from the connection's channel pool and
+// puts it into publisher confirms mode.
+func (p *Publisher) openChannel() (publisherChannel, <-chan amqp.Confirmation, <-chan amqp.Return, error) {
+	ch, err := p.conn.Channels().Acquire()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, nil, nil, fmt.Errorf("enable confirms: %w", err)
+	}
+
+	confirms := ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	returns := ch.NotifyReturn(make(chan amqp.Return, 1))
+
+	return ch, confirms, returns, nil
+}
+
+// channelLocked returns the Publisher's current channel, opening one if it
+// doesn't have one yet. Callers must hold p.mu.
+func (p *Publisher) channelLocked() (publisherChannel, <-chan amqp.Confirmation, <-chan amqp.Return, error) {
+	if p.ch != nil {
+		return p.ch, p.confirms, p.returns, nil
+	}
+
+	ch, confirms, returns, err := p.newChannel()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	p.ch, p.confirms, p.returns = ch, confirms, returns
+	return ch, confirms, returns, nil
+}
+
+// invalidateChannelLocked discards the Publisher's current channel so the
+// next publish attempt opens a fresh one. Called after any error that may
+// have left the channel in a bad state. Callers must hold p.mu.
+func (p *Publisher) invalidateChannelLocked() {
+	if p.ch != nil {
+		p.ch.Close()
+	}
+	p.ch, p.confirms, p.returns = nil, nil, nil
+}
+
+// publishOnce runs a single publish-and-wait-for-confirm attempt under p.mu,
+// so concurrent Publish calls never share a confirm/return off the same
+// channel: without delivery-tag correlation, a confirm meant for one
+// goroutine's publish could otherwise be consumed by another's, reporting a
+// nack as a success (or vice versa).
+func (p *Publisher) publishOnce(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch, confirms, returns, err := p.channelLocked()
+	if err != nil {
+		return err
+	}
+
+	if err := ch.PublishWithContext(ctx, exchange, key, true, false, msg); err != nil {
+		p.invalidateChannelLocked()
+		return err
+	}
+
+	confirmCtx := ctx
+	var cancel context.CancelFunc
+	if p.confirm > 0 {
+		confirmCtx, cancel = context.WithTimeout(ctx, p.confirm)
+		defer cancel()
+	}
+
+	select {
+	case <-confirmCtx.Done():
+		p.invalidateChannelLocked()
+		return confirmCtx.Err()
+	case ret := <-returns:
+		// The broker still sends a confirm for a returned message; discard
+		// the channel so that confirm is not read by the next attempt.
+		p.invalidateChannelLocked()
+		return fmt.Errorf("%w: returned (%d %s)", ErrPublishRejected, ret.ReplyCode, ret.ReplyText)
+	case confirm, ok := <-confirms:
+		if !ok {
+			p.invalidateChannelLocked()
+			return ErrNotConnected
+		}
+		if !confirm.Ack {
+			return ErrPublishRejected
+		}
+		return nil
+	}
+}
+
+// backoff computes the delay before the given retry attempt (1-indexed),
+// capped at MaxDelay and jittered by ±JitterFactor.
+func (p *Publisher) backoff(attempt int) time.Duration {
+	base := float64(p.retry.InitialDelay) * math.Pow(p.retry.BackoffMultiplier, float64(attempt-1))
+	if max := float64(p.retry.MaxDelay); base > max {
+		base = max
+	}
+
+	jitter := 1 + p.retry.JitterFactor*(2*rand.Float64()-1)
+	return time.Duration(base * jitter)
+}
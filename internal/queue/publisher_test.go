@@ -0,0 +1,331 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// fakePublisherChannel implements publisherChannel for tests. Each call to
+// PublishWithContext consumes the next entry in results (if any) to push a
+// confirmation or return onto the shared confirms/returns channels,
+// deterministically driving Publisher's retry paths without a real broker.
+type fakePublisherChannel struct {
+	mu         sync.Mutex
+	published  int
+	closed     bool
+	publishErr error
+	confirms   chan amqp.Confirmation
+	returns    chan amqp.Return
+	results    []func(confirms chan amqp.Confirmation, returns chan amqp.Return)
+	msgs       []amqp.Publishing
+	keys       []string
+}
+
+func (f *fakePublisherChannel) Confirm(noWait bool) error { return nil }
+
+func (f *fakePublisherChannel) NotifyPublish(chan amqp.Confirmation) chan amqp.Confirmation {
+	return f.confirms
+}
+
+func (f *fakePublisherChannel) NotifyReturn(chan amqp.Return) chan amqp.Return {
+	return f.returns
+}
+
+func (f *fakePublisherChannel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	f.mu.Lock()
+	i := f.published
+	f.published++
+	f.msgs = append(f.msgs, msg)
+	f.keys = append(f.keys, key)
+	f.mu.Unlock()
+
+	if f.publishErr != nil {
+		return f.publishErr
+	}
+	if i < len(f.results) && f.results[i] != nil {
+		f.results[i](f.confirms, f.returns)
+	}
+	return nil
+}
+
+func (f *fakePublisherChannel) Close() error {
+	f.closed = true
+	return nil
+}
+
+func newTestPublisher(retry RetryConfig, confirmTimeout time.Duration, fake *fakePublisherChannel) (*Publisher, *int) {
+	opens := 0
+	p := &Publisher{
+		retry:   retry,
+		logger:  slog.Default(),
+		confirm: confirmTimeout,
+	}
+	p.newChannel = func() (publisherChannel, <-chan amqp.Confirmation, <-chan amqp.Return, error) {
+		opens++
+		return fake, fake.confirms, fake.returns, nil
+	}
+	return p, &opens
+}
+
+func TestPublisher_Backoff(t *testing.T) {
+	t.Run("grows exponentially and caps at MaxDelay", func(t *testing.T) {
+		p := &Publisher{
+			retry: RetryConfig{
+				InitialDelay:      1 * time.Second,
+				MaxDelay:          10 * time.Second,
+				BackoffMultiplier: 2,
+				JitterFactor:      0,
+			},
+		}
+
+		delays := []time.Duration{
+			p.backoff(1),
+			p.backoff(2),
+			p.backoff(3),
+			p.backoff(4),
+			p.backoff(5),
+		}
+
+		want := []time.Duration{
+			1 * time.Second,
+			2 * time.Second,
+			4 * time.Second,
+			8 * time.Second,
+			10 * time.Second, // capped
+		}
+
+		for i, d := range delays {
+			if d != want[i] {
+				t.Errorf("attempt %d: expected %v, got %v", i+1, want[i], d)
+			}
+		}
+	})
+
+	t.Run("applies jitter within the configured factor", func(t *testing.T) {
+		p := &Publisher{
+			retry: RetryConfig{
+				InitialDelay:      10 * time.Second,
+				MaxDelay:          time.Minute,
+				BackoffMultiplier: 1,
+				JitterFactor:      0.2,
+			},
+		}
+
+		min := 8 * time.Second
+		max := 12 * time.Second
+
+		for i := 0; i < 50; i++ {
+			d := p.backoff(1)
+			if d < min || d > max {
+				t.Fatalf("delay %v outside jitter bounds [%v, %v]", d, min, max)
+			}
+		}
+	})
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	retry := RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffMultiplier: 1}
+
+	t.Run("succeeds on the first attempt and reuses the channel across calls", func(t *testing.T) {
+		fake := &fakePublisherChannel{
+			confirms: make(chan amqp.Confirmation, 1),
+			returns:  make(chan amqp.Return, 1),
+			results: []func(chan amqp.Confirmation, chan amqp.Return){
+				func(c chan amqp.Confirmation, r chan amqp.Return) { c <- amqp.Confirmation{Ack: true} },
+				func(c chan amqp.Confirmation, r chan amqp.Return) { c <- amqp.Confirmation{Ack: true} },
+			},
+		}
+		p, opens := newTestPublisher(retry, 0, fake)
+
+		for i := 0; i < 2; i++ {
+			if err := p.Publish(context.Background(), "ex", "key", amqp.Publishing{}); err != nil {
+				t.Fatalf("call %d: unexpected error: %v", i, err)
+			}
+		}
+
+		if *opens != 1 {
+			t.Errorf("expected the channel to be opened once, got %d", *opens)
+		}
+		if fake.published != 2 {
+			t.Errorf("expected 2 publishes, got %d", fake.published)
+		}
+	})
+
+	t.Run("retries after a nacked confirm and succeeds", func(t *testing.T) {
+		fake := &fakePublisherChannel{
+			confirms: make(chan amqp.Confirmation, 1),
+			returns:  make(chan amqp.Return, 1),
+			results: []func(chan amqp.Confirmation, chan amqp.Return){
+				func(c chan amqp.Confirmation, r chan amqp.Return) { c <- amqp.Confirmation{Ack: false} },
+				func(c chan amqp.Confirmation, r chan amqp.Return) { c <- amqp.Confirmation{Ack: true} },
+			},
+		}
+		p, _ := newTestPublisher(retry, 0, fake)
+
+		if err := p.Publish(context.Background(), "ex", "key", amqp.Publishing{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if fake.published != 2 {
+			t.Errorf("expected 2 publish attempts, got %d", fake.published)
+		}
+	})
+
+	t.Run("retries after a returned message and succeeds", func(t *testing.T) {
+		fake := &fakePublisherChannel{
+			confirms: make(chan amqp.Confirmation, 1),
+			returns:  make(chan amqp.Return, 1),
+			results: []func(chan amqp.Confirmation, chan amqp.Return){
+				func(c chan amqp.Confirmation, r chan amqp.Return) {
+					r <- amqp.Return{ReplyCode: 312, ReplyText: "NO_ROUTE"}
+				},
+				func(c chan amqp.Confirmation, r chan amqp.Return) { c <- amqp.Confirmation{Ack: true} },
+			},
+		}
+		p, _ := newTestPublisher(retry, 0, fake)
+
+		if err := p.Publish(context.Background(), "ex", "key", amqp.Publishing{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if fake.published != 2 {
+			t.Errorf("expected 2 publish attempts, got %d", fake.published)
+		}
+	})
+
+	t.Run("retries after the confirm wait times out and succeeds", func(t *testing.T) {
+		fake := &fakePublisherChannel{
+			confirms: make(chan amqp.Confirmation, 1),
+			returns:  make(chan amqp.Return, 1),
+			results: []func(chan amqp.Confirmation, chan amqp.Return){
+				nil,
+				func(c chan amqp.Confirmation, r chan amqp.Return) { c <- amqp.Confirmation{Ack: true} },
+			},
+		}
+		p, opens := newTestPublisher(retry, 10*time.Millisecond, fake)
+
+		if err := p.Publish(context.Background(), "ex", "key", amqp.Publishing{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if fake.published != 2 {
+			t.Errorf("expected 2 publish attempts, got %d", fake.published)
+		}
+		if *opens != 2 {
+			t.Errorf("expected the timed-out channel to be discarded and reopened, got %d opens", *opens)
+		}
+	})
+
+	t.Run("gives up after MaxAttempts nacks", func(t *testing.T) {
+		fake := &fakePublisherChannel{
+			confirms: make(chan amqp.Confirmation, 1),
+			returns:  make(chan amqp.Return, 1),
+			results: []func(chan amqp.Confirmation, chan amqp.Return){
+				func(c chan amqp.Confirmation, r chan amqp.Return) { c <- amqp.Confirmation{Ack: false} },
+				func(c chan amqp.Confirmation, r chan amqp.Return) { c <- amqp.Confirmation{Ack: false} },
+				func(c chan amqp.Confirmation, r chan amqp.Return) { c <- amqp.Confirmation{Ack: false} },
+			},
+		}
+		p, _ := newTestPublisher(retry, 0, fake)
+
+		err := p.Publish(context.Background(), "ex", "key", amqp.Publishing{})
+		if !errors.Is(err, ErrPublishRejected) {
+			t.Fatalf("expected ErrPublishRejected, got %v", err)
+		}
+		if fake.published != retry.MaxAttempts {
+			t.Errorf("expected %d publish attempts, got %d", retry.MaxAttempts, fake.published)
+		}
+	})
+
+	t.Run("reopens the channel after a publish-level error", func(t *testing.T) {
+		fake := &fakePublisherChannel{
+			confirms:   make(chan amqp.Confirmation, 1),
+			returns:    make(chan amqp.Return, 1),
+			publishErr: errors.New("channel/connection is not open"),
+		}
+		p, opens := newTestPublisher(retry, 0, fake)
+
+		err := p.Publish(context.Background(), "ex", "key", amqp.Publishing{})
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if !fake.closed {
+			t.Error("expected the broken channel to be closed")
+		}
+		if *opens != retry.MaxAttempts {
+			t.Errorf("expected a fresh channel per attempt after the error, got %d opens", *opens)
+		}
+	})
+
+	t.Run("calls OnPublish once per Publish call with the final result", func(t *testing.T) {
+		fake := &fakePublisherChannel{
+			confirms: make(chan amqp.Confirmation, 1),
+			returns:  make(chan amqp.Return, 1),
+			results: []func(chan amqp.Confirmation, chan amqp.Return){
+				func(c chan amqp.Confirmation, r chan amqp.Return) { c <- amqp.Confirmation{Ack: true} },
+			},
+		}
+		p, _ := newTestPublisher(retry, 0, fake)
+
+		var gotErr error
+		calls := 0
+		p.OnPublish = func(err error, d time.Duration) {
+			calls++
+			gotErr = err
+		}
+
+		if err := p.Publish(context.Background(), "ex", "key", amqp.Publishing{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("expected OnPublish to be called once, got %d", calls)
+		}
+		if gotErr != nil {
+			t.Errorf("expected a nil error, got %v", gotErr)
+		}
+	})
+
+	t.Run("concurrent Publish calls all succeed over a single shared channel", func(t *testing.T) {
+		const n = 20
+		fake := &fakePublisherChannel{
+			confirms: make(chan amqp.Confirmation, 1),
+			returns:  make(chan amqp.Return, 1),
+		}
+		// Every attempt acks, so if two Publish calls ever ran publishOnce
+		// concurrently, each would still observe a successful confirm; this
+		// checks that n concurrent calls all succeed, with exactly n
+		// publishes, over one channel opened once.
+		fake.results = make([]func(chan amqp.Confirmation, chan amqp.Return), n)
+		for i := range fake.results {
+			fake.results[i] = func(c chan amqp.Confirmation, r chan amqp.Return) { c <- amqp.Confirmation{Ack: true} }
+		}
+		p, opens := newTestPublisher(retry, 0, fake)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- p.Publish(context.Background(), "ex", "key", amqp.Publishing{})
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+		if fake.published != n {
+			t.Errorf("expected %d publishes, got %d", n, fake.published)
+		}
+		if *opens != 1 {
+			t.Errorf("expected the channel to be opened once, got %d", *opens)
+		}
+	})
+}
@@ -2,8 +2,11 @@ package queue
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"log/slog"
+	"net"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,33 +16,178 @@ import (
 var (
 	ErrNotConnected = errors.New("not connected to RabbitMQ")
 	ErrClosed       = errors.New("connection is closed")
+	ErrNoURLs       = errors.New("url(s) must be not empty")
 )
 
+// DialFunc dials a RabbitMQ broker at url using cfg and returns the
+// resulting connection. It is a field on Connection (rather than a package
+// function) so tests can inject a fake dialer.
+type DialFunc func(url string, cfg amqp.Config) (*amqp.Connection, error)
+
 // Connection manages a RabbitMQ connection with automatic reconnection.
+// Connection dials a cluster of one or more brokers in round-robin order,
+// resuming after the last URL that succeeded rather than always starting
+// from the front of the list.
 type Connection struct {
-	url    string
-	conn   *amqp.Connection
-	mu     sync.RWMutex
-	closed bool
-	done   chan struct{}
-	logger *slog.Logger
+	urls     []string
+	urlIndex int
+	conn     *amqp.Connection
+	mu       sync.RWMutex
+	closed   bool
+	done     chan struct{}
+	logger   *slog.Logger
+
+	// amqpConfig is passed to DialFunc on every dial attempt; it carries
+	// TLS, heartbeat, locale and connection-name settings.
+	amqpConfig amqp.Config
+
+	// DialFunc performs the actual dial. Defaults to amqp.DialConfig;
+	// overridable for tests.
+	DialFunc DialFunc
 
 	// Reconnection settings
 	reconnectDelay    time.Duration
 	maxReconnectDelay time.Duration
+
+	reconnectMu   sync.Mutex
+	reconnectSubs map[chan struct{}]struct{}
+
+	consumersMu sync.RWMutex
+	consumers   map[string]bool
+
+	// OnReconnectAttempt, if set, is called before every pass over the URL
+	// list made by reconnect (success or failure) with the backoff delay
+	// that was waited beforehand. OnReconnect, if set, is called after a successful
+	// reconnect. OnConsume, if set, is called every time a StartConsumer
+	// run of Consumer.Consume returns, with its error. None are required;
+	// they exist so a metrics package can observe connection and consumer
+	// behaviour without queue depending on prometheus.
+	OnReconnectAttempt func(delay time.Duration)
+	OnReconnect        func()
+	OnConsume          func(err error)
+}
+
+// Option configures a Connection constructed by NewConnection.
+type Option func(*Connection)
+
+// WithTLSConfig enables TLS on the underlying AMQP connection.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *Connection) {
+		c.amqpConfig.TLSClientConfig = tlsConfig
+	}
 }
 
-// NewConnection creates a new Connection instance.
-func NewConnection(url string, logger *slog.Logger) *Connection {
+// WithHeartbeat sets the AMQP heartbeat interval negotiated with the broker.
+func WithHeartbeat(d time.Duration) Option {
+	return func(c *Connection) {
+		c.amqpConfig.Heartbeat = d
+	}
+}
+
+// WithConnectionName sets the connection_name client property so operators
+// can identify this service in the RabbitMQ management UI.
+func WithConnectionName(name string) Option {
+	return func(c *Connection) {
+		if c.amqpConfig.Properties == nil {
+			c.amqpConfig.Properties = amqp.NewConnectionProperties()
+		}
+		c.amqpConfig.Properties["connection_name"] = name
+	}
+}
+
+// WithDialTimeout bounds how long a single dial attempt may take.
+func WithDialTimeout(d time.Duration) Option {
+	return func(c *Connection) {
+		dialer := &net.Dialer{Timeout: d}
+		c.amqpConfig.Dial = dialer.Dial
+	}
+}
+
+// WithURLs replaces the connection's broker list, trimming whitespace and
+// dropping empty entries. Use it instead of a comma-separated string when
+// the URLs are already in hand as a slice.
+func WithURLs(urls ...string) Option {
+	return func(c *Connection) {
+		c.urls = parseURLList(urls)
+	}
+}
+
+// parseURLList trims whitespace from each URL and drops empty entries.
+func parseURLList(urls []string) []string {
+	var out []string
+	for _, u := range urls {
+		u = strings.TrimSpace(u)
+		if u != "" {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// NewConnection creates a new Connection instance. url may be a single
+// broker address or a comma-separated list for cluster failover.
+func NewConnection(url string, logger *slog.Logger, opts ...Option) *Connection {
 	if logger == nil {
 		logger = slog.Default()
 	}
-	return &Connection{
-		url:               url,
+	c := &Connection{
+		urls:              parseURLList(strings.Split(url, ",")),
 		done:              make(chan struct{}),
 		logger:            logger,
 		reconnectDelay:    1 * time.Second,
 		maxReconnectDelay: 30 * time.Second,
+		amqpConfig:        amqp.Config{Heartbeat: 10 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.DialFunc = func(url string, cfg amqp.Config) (*amqp.Connection, error) {
+		return amqp.DialConfig(url, cfg)
+	}
+
+	return c
+}
+
+// NotifyReconnect registers a channel that receives a value every time the
+// connection becomes usable: both the initial Connect and every subsequent
+// reconnect after a loss signal it. Callers use it to redeclare topology and
+// resume consuming instead of racing on IsConnected, including a consumer
+// started via StartConsumer before Connect has returned.
+// The returned channel is buffered by one; a pending notification is never
+// overwritten, but a slow receiver can still miss a subsequent reconnect.
+// The returned cancel func unsubscribes ch; callers that call NotifyReconnect
+// in a loop (e.g. to wait on each reconnect in turn) must call it once done
+// with the channel, or reconnectSubs grows without bound.
+func (c *Connection) NotifyReconnect() (ch <-chan struct{}, cancel func()) {
+	sub := make(chan struct{}, 1)
+
+	c.reconnectMu.Lock()
+	if c.reconnectSubs == nil {
+		c.reconnectSubs = make(map[chan struct{}]struct{})
+	}
+	c.reconnectSubs[sub] = struct{}{}
+	c.reconnectMu.Unlock()
+
+	return sub, func() {
+		c.reconnectMu.Lock()
+		delete(c.reconnectSubs, sub)
+		c.reconnectMu.Unlock()
+	}
+}
+
+// notifyReconnected fans out a reconnect signal to every subscriber
+// registered via NotifyReconnect.
+func (c *Connection) notifyReconnected() {
+	c.reconnectMu.Lock()
+	defer c.reconnectMu.Unlock()
+
+	for ch := range c.reconnectSubs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
 	}
 }
 
@@ -52,19 +200,58 @@ func (c *Connection) Connect(ctx context.Context) error {
 		return ErrClosed
 	}
 
-	conn, err := amqp.Dial(c.url)
+	if len(c.urls) == 0 {
+		return ErrNoURLs
+	}
+
+	conn, idx, err := c.dialRoundRobin(ctx, c.urlIndex)
 	if err != nil {
 		return err
 	}
 
 	c.conn = conn
-	c.logger.Info("connected to RabbitMQ")
+	c.urlIndex = (idx + 1) % len(c.urls)
+	c.logger.Info("connected to RabbitMQ", "url_index", idx)
+
+	// Wake anyone already blocked in waitForReconnect (e.g. a consumer
+	// started via StartConsumer before Connect returned), same as a
+	// mid-life reconnect would.
+	c.notifyReconnected()
 
 	go c.handleReconnect(ctx)
 
 	return nil
 }
 
+// dialRoundRobin tries each URL once, starting at startIndex and wrapping
+// around the list, returning the first successful connection along with the
+// index it was dialed from. If every URL fails, it returns the last error
+// seen.
+func (c *Connection) dialRoundRobin(ctx context.Context, startIndex int) (*amqp.Connection, int, error) {
+	n := len(c.urls)
+	var lastErr error
+
+	for i := 0; i < n; i++ {
+		idx := (startIndex + i) % n
+
+		select {
+		case <-ctx.Done():
+			return nil, idx, ctx.Err()
+		default:
+		}
+
+		conn, err := c.DialFunc(c.urls[idx], c.amqpConfig)
+		if err == nil {
+			return conn, idx, nil
+		}
+
+		lastErr = err
+		c.logger.Warn("dial failed", "url_index", idx, "error", err)
+	}
+
+	return nil, startIndex, lastErr
+}
+
 // handleReconnect listens for connection closures and attempts to reconnect.
 func (c *Connection) handleReconnect(ctx context.Context) {
 	for {
@@ -99,7 +286,9 @@ func (c *Connection) handleReconnect(ctx context.Context) {
 	}
 }
 
-// reconnect attempts to reconnect with exponential backoff.
+// reconnect attempts to reconnect with exponential backoff. Backoff applies
+// only between full passes over the URL list, so a downed node fails over
+// to the next one within milliseconds rather than waiting out the delay.
 func (c *Connection) reconnect(ctx context.Context) {
 	delay := c.reconnectDelay
 
@@ -109,6 +298,7 @@ func (c *Connection) reconnect(ctx context.Context) {
 			c.mu.RUnlock()
 			return
 		}
+		startIndex := c.urlIndex
 		c.mu.RUnlock()
 
 		select {
@@ -121,7 +311,11 @@ func (c *Connection) reconnect(ctx context.Context) {
 
 		c.logger.Info("attempting to reconnect", "delay", delay)
 
-		conn, err := amqp.Dial(c.url)
+		if c.OnReconnectAttempt != nil {
+			c.OnReconnectAttempt(delay)
+		}
+
+		conn, idx, err := c.dialRoundRobin(ctx, startIndex)
 		if err != nil {
 			c.logger.Warn("reconnection failed", "error", err, "next_delay", delay*2)
 
@@ -139,9 +333,14 @@ func (c *Connection) reconnect(ctx context.Context) {
 			return
 		}
 		c.conn = conn
+		c.urlIndex = (idx + 1) % len(c.urls)
 		c.mu.Unlock()
 
-		c.logger.Info("reconnected to RabbitMQ")
+		c.logger.Info("reconnected to RabbitMQ", "url_index", idx)
+		c.notifyReconnected()
+		if c.OnReconnect != nil {
+			c.OnReconnect()
+		}
 		return
 	}
 }
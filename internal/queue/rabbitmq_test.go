@@ -17,8 +17,8 @@ func TestNewConnection(t *testing.T) {
 		if conn == nil {
 			t.Fatal("expected connection to be created")
 		}
-		if conn.url != url {
-			t.Errorf("expected url %q, got %q", url, conn.url)
+		if len(conn.urls) != 1 || conn.urls[0] != url {
+			t.Errorf("expected urls %v, got %v", []string{url}, conn.urls)
 		}
 		if conn.logger != logger {
 			t.Error("expected logger to be set")
@@ -144,3 +144,49 @@ func TestConnection_Connect(t *testing.T) {
 		}
 	})
 }
+
+func TestConnection_NotifyReconnect(t *testing.T) {
+	t.Run("fans out to every subscriber", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil)
+
+		sub1, cancel1 := conn.NotifyReconnect()
+		defer cancel1()
+		sub2, cancel2 := conn.NotifyReconnect()
+		defer cancel2()
+
+		conn.notifyReconnected()
+
+		select {
+		case <-sub1:
+		default:
+			t.Error("expected first subscriber to be notified")
+		}
+		select {
+		case <-sub2:
+		default:
+			t.Error("expected second subscriber to be notified")
+		}
+	})
+
+	t.Run("does not block when a subscriber already has a pending notification", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil)
+		_, cancel := conn.NotifyReconnect()
+		defer cancel()
+
+		conn.notifyReconnected()
+		conn.notifyReconnected()
+	})
+
+	t.Run("cancel unsubscribes so reconnectSubs does not grow unbounded", func(t *testing.T) {
+		conn := NewConnection("amqp://localhost:5672/", nil)
+
+		for i := 0; i < 5; i++ {
+			_, cancel := conn.NotifyReconnect()
+			cancel()
+		}
+
+		if got := len(conn.reconnectSubs); got != 0 {
+			t.Errorf("expected reconnectSubs to be empty after cancel, got %d entries", got)
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// AttemptHeader is the message header Nack increments on every retry so a
+// consumer can tell how many times a delivery has been redelivered.
+const AttemptHeader = "x-attempt"
+
+// topologyChannel is the subset of *amqp.Channel Declare needs, extracted so
+// tests can inject a fake instead of dialing a real broker.
+type topologyChannel interface {
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+	Close() error
+}
+
+// TopologyOptions configures the queues and bindings a Topology declares.
+type TopologyOptions struct {
+	// Exchange is the topic exchange deliveries are published to and
+	// retried deliveries are dead-lettered back to. Defaults to name.
+	Exchange string
+
+	// RoutingKey binds the main queue to Exchange. Defaults to "#".
+	RoutingKey string
+
+	// Retry drives the retry queue's per-attempt delay and the attempt
+	// count at which a delivery is parked in the DLQ instead of retried.
+	Retry RetryConfig
+}
+
+// Topology declares the standard work-queue-with-retry-and-DLQ pattern: a
+// main queue bound to a topic exchange, a "<name>.retry" queue whose
+// messages dead-letter back to that exchange once their per-message TTL
+// expires, and a "<name>.dlq" parking lot for deliveries that exceed
+// Retry.MaxAttempts.
+type Topology struct {
+	conn *Connection
+	name string
+	opts TopologyOptions
+
+	// newChannel acquires the channel Declare declares topology on.
+	// Overridable in tests.
+	newChannel func() (topologyChannel, error)
+}
+
+// NewTopology creates a Topology for name, backed by conn.
+func NewTopology(conn *Connection, name string, opts TopologyOptions) *Topology {
+	t := &Topology{conn: conn, name: name, opts: opts}
+	t.newChannel = func() (topologyChannel, error) {
+		return conn.Channels().Acquire()
+	}
+	return t
+}
+
+// RetryQueueName returns the name of the retry queue.
+func (t *Topology) RetryQueueName() string {
+	return t.name + ".retry"
+}
+
+// DLQName returns the name of the parking-lot dead-letter queue.
+func (t *Topology) DLQName() string {
+	return t.name + ".dlq"
+}
+
+func (t *Topology) exchange() string {
+	if t.opts.Exchange != "" {
+		return t.opts.Exchange
+	}
+	return t.name
+}
+
+func (t *Topology) routingKey() string {
+	if t.opts.RoutingKey != "" {
+		return t.opts.RoutingKey
+	}
+	return "#"
+}
+
+// Declare creates the exchange, main queue, retry queue and DLQ. It is safe
+// to call repeatedly; every declaration is idempotent.
+func (t *Topology) Declare(ctx context.Context) error {
+	ch, err := t.newChannel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclare(t.exchange(), "topic", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("declare exchange: %w", err)
+	}
+
+	if _, err := ch.QueueDeclare(t.name, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("declare queue: %w", err)
+	}
+
+	if err := ch.QueueBind(t.name, t.routingKey(), t.exchange(), false, nil); err != nil {
+		return fmt.Errorf("bind queue: %w", err)
+	}
+
+	retryArgs := amqp.Table{
+		"x-dead-letter-exchange":    t.exchange(),
+		"x-dead-letter-routing-key": t.routingKey(),
+	}
+	if _, err := ch.QueueDeclare(t.RetryQueueName(), true, false, false, false, retryArgs); err != nil {
+		return fmt.Errorf("declare retry queue: %w", err)
+	}
+
+	if _, err := ch.QueueDeclare(t.DLQName(), true, false, false, false, nil); err != nil {
+		return fmt.Errorf("declare dlq: %w", err)
+	}
+
+	return nil
+}
+
+// retryDelay computes the TTL for the given attempt (1-indexed), capped at
+// Retry.MaxDelay. AMQP has no per-queue dynamic TTL, so Nack applies this as
+// a per-message "expiration" on the republished delivery instead.
+func (t *Topology) retryDelay(attempt int) time.Duration {
+	delay := float64(t.opts.Retry.InitialDelay) * math.Pow(t.opts.Retry.BackoffMultiplier, float64(attempt-1))
+	if max := float64(t.opts.Retry.MaxDelay); delay > max {
+		delay = max
+	}
+	return time.Duration(delay)
+}
+
+// Nack republishes msg to the retry queue with AttemptHeader set to
+// attempt+1, or to the DLQ once attempt+1 reaches Retry.MaxAttempts, then acknowledges
+// the original delivery. Handing the delivery off this way means no
+// goroutine sleeps while holding it.
+func (t *Topology) Nack(ctx context.Context, pub *Publisher, msg amqp.Delivery, attempt int) error {
+	nextAttempt := attempt + 1
+
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[AttemptHeader] = int32(nextAttempt)
+
+	republish := amqp.Publishing{
+		Headers:      headers,
+		ContentType:  msg.ContentType,
+		DeliveryMode: msg.DeliveryMode,
+		Body:         msg.Body,
+	}
+
+	var err error
+	if nextAttempt >= t.opts.Retry.MaxAttempts {
+		err = pub.Publish(ctx, "", t.DLQName(), republish)
+	} else {
+		republish.Expiration = strconv.FormatInt(t.retryDelay(nextAttempt).Milliseconds(), 10)
+		err = pub.Publish(ctx, "", t.RetryQueueName(), republish)
+	}
+	if err != nil {
+		return fmt.Errorf("republish delivery: %w", err)
+	}
+
+	return msg.Ack(false)
+}
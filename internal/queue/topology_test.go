@@ -0,0 +1,304 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// fakeTopologyChannel implements topologyChannel for tests, recording every
+// declare/bind call so Declare's arguments can be asserted without a broker.
+type fakeTopologyChannel struct {
+	closed bool
+
+	exchangeDeclares []fakeExchangeDeclare
+	queueDeclares    []fakeQueueDeclare
+	queueBinds       []fakeQueueBind
+}
+
+type fakeExchangeDeclare struct {
+	name, kind                            string
+	durable, autoDelete, internal, noWait bool
+	args                                  amqp.Table
+}
+
+type fakeQueueDeclare struct {
+	name                                   string
+	durable, autoDelete, exclusive, noWait bool
+	args                                   amqp.Table
+}
+
+type fakeQueueBind struct {
+	name, key, exchange string
+	noWait              bool
+	args                amqp.Table
+}
+
+func (f *fakeTopologyChannel) ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error {
+	f.exchangeDeclares = append(f.exchangeDeclares, fakeExchangeDeclare{name, kind, durable, autoDelete, internal, noWait, args})
+	return nil
+}
+
+func (f *fakeTopologyChannel) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+	f.queueDeclares = append(f.queueDeclares, fakeQueueDeclare{name, durable, autoDelete, exclusive, noWait, args})
+	return amqp.Queue{Name: name}, nil
+}
+
+func (f *fakeTopologyChannel) QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error {
+	f.queueBinds = append(f.queueBinds, fakeQueueBind{name, key, exchange, noWait, args})
+	return nil
+}
+
+func (f *fakeTopologyChannel) Close() error {
+	f.closed = true
+	return nil
+}
+
+// fakeAcknowledger implements amqp.Acknowledger so a Delivery can be acked in
+// tests without a broker.
+type fakeAcknowledger struct {
+	acked bool
+}
+
+func (f *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	f.acked = true
+	return nil
+}
+
+func (f *fakeAcknowledger) Nack(tag uint64, multiple, requeue bool) error { return nil }
+func (f *fakeAcknowledger) Reject(tag uint64, requeue bool) error         { return nil }
+
+func newTestTopology(name string, opts TopologyOptions, fake *fakeTopologyChannel) *Topology {
+	topo := NewTopology(nil, name, opts)
+	topo.newChannel = func() (topologyChannel, error) {
+		return fake, nil
+	}
+	return topo
+}
+
+func TestTopology_Names(t *testing.T) {
+	topo := NewTopology(nil, "notifications", TopologyOptions{})
+
+	if got := topo.RetryQueueName(); got != "notifications.retry" {
+		t.Errorf("expected %q, got %q", "notifications.retry", got)
+	}
+	if got := topo.DLQName(); got != "notifications.dlq" {
+		t.Errorf("expected %q, got %q", "notifications.dlq", got)
+	}
+	if got := topo.exchange(); got != "notifications" {
+		t.Errorf("expected exchange to default to name, got %q", got)
+	}
+	if got := topo.routingKey(); got != "#" {
+		t.Errorf("expected routing key to default to #, got %q", got)
+	}
+}
+
+func TestTopology_ExplicitExchangeAndRoutingKey(t *testing.T) {
+	topo := NewTopology(nil, "notifications", TopologyOptions{
+		Exchange:   "notifications.topic",
+		RoutingKey: "notifications.sms.#",
+	})
+
+	if got := topo.exchange(); got != "notifications.topic" {
+		t.Errorf("expected %q, got %q", "notifications.topic", got)
+	}
+	if got := topo.routingKey(); got != "notifications.sms.#" {
+		t.Errorf("expected %q, got %q", "notifications.sms.#", got)
+	}
+}
+
+func TestTopology_RetryDelay(t *testing.T) {
+	topo := NewTopology(nil, "notifications", TopologyOptions{
+		Retry: RetryConfig{
+			InitialDelay:      1 * time.Second,
+			MaxDelay:          10 * time.Second,
+			BackoffMultiplier: 2,
+		},
+	})
+
+	want := []time.Duration{
+		1 * time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		10 * time.Second, // capped
+	}
+
+	for i, w := range want {
+		if got := topo.retryDelay(i + 1); got != w {
+			t.Errorf("attempt %d: expected %v, got %v", i+1, w, got)
+		}
+	}
+}
+
+func TestTopology_Declare(t *testing.T) {
+	t.Run("declares the exchange, main queue, binding, retry queue and dlq", func(t *testing.T) {
+		fake := &fakeTopologyChannel{}
+		topo := newTestTopology("notifications", TopologyOptions{}, fake)
+
+		if err := topo.Declare(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(fake.exchangeDeclares) != 1 {
+			t.Fatalf("expected 1 exchange declare, got %d", len(fake.exchangeDeclares))
+		}
+		ex := fake.exchangeDeclares[0]
+		if ex.name != "notifications" || ex.kind != "topic" || !ex.durable {
+			t.Errorf("unexpected exchange declare: %+v", ex)
+		}
+
+		if len(fake.queueDeclares) != 3 {
+			t.Fatalf("expected 3 queue declares (main, retry, dlq), got %d", len(fake.queueDeclares))
+		}
+
+		main := fake.queueDeclares[0]
+		if main.name != "notifications" || !main.durable || main.args != nil {
+			t.Errorf("unexpected main queue declare: %+v", main)
+		}
+
+		retry := fake.queueDeclares[1]
+		if retry.name != "notifications.retry" || !retry.durable {
+			t.Errorf("unexpected retry queue declare: %+v", retry)
+		}
+		if got := retry.args["x-dead-letter-exchange"]; got != "notifications" {
+			t.Errorf("expected retry queue to dead-letter to exchange %q, got %v", "notifications", got)
+		}
+		if got := retry.args["x-dead-letter-routing-key"]; got != "#" {
+			t.Errorf("expected retry queue dead-letter routing key %q, got %v", "#", got)
+		}
+
+		dlq := fake.queueDeclares[2]
+		if dlq.name != "notifications.dlq" || !dlq.durable || dlq.args != nil {
+			t.Errorf("unexpected dlq declare: %+v", dlq)
+		}
+
+		if len(fake.queueBinds) != 1 {
+			t.Fatalf("expected 1 queue bind, got %d", len(fake.queueBinds))
+		}
+		bind := fake.queueBinds[0]
+		if bind.name != "notifications" || bind.key != "#" || bind.exchange != "notifications" {
+			t.Errorf("unexpected queue bind: %+v", bind)
+		}
+
+		if !fake.closed {
+			t.Error("expected the channel to be closed after Declare")
+		}
+	})
+
+	t.Run("uses the configured exchange and routing key", func(t *testing.T) {
+		fake := &fakeTopologyChannel{}
+		topo := newTestTopology("notifications", TopologyOptions{
+			Exchange:   "notifications.topic",
+			RoutingKey: "notifications.sms.#",
+		}, fake)
+
+		if err := topo.Declare(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := fake.exchangeDeclares[0].name; got != "notifications.topic" {
+			t.Errorf("expected exchange %q, got %q", "notifications.topic", got)
+		}
+		if got := fake.queueBinds[0].key; got != "notifications.sms.#" {
+			t.Errorf("expected routing key %q, got %q", "notifications.sms.#", got)
+		}
+	})
+}
+
+func TestTopology_Nack(t *testing.T) {
+	retry := RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffMultiplier: 1}
+
+	newAckedPublisher := func() (*Publisher, *fakePublisherChannel) {
+		fake := &fakePublisherChannel{
+			confirms: make(chan amqp.Confirmation, 1),
+			returns:  make(chan amqp.Return, 1),
+			results: []func(chan amqp.Confirmation, chan amqp.Return){
+				func(c chan amqp.Confirmation, r chan amqp.Return) { c <- amqp.Confirmation{Ack: true} },
+			},
+		}
+		pub, _ := newTestPublisher(retry, 0, fake)
+		return pub, fake
+	}
+
+	t.Run("republishes to the retry queue and increments the attempt header below MaxAttempts", func(t *testing.T) {
+		pub, fake := newAckedPublisher()
+		topo := NewTopology(nil, "notifications", TopologyOptions{Retry: retry})
+		ack := &fakeAcknowledger{}
+		msg := amqp.Delivery{Acknowledger: ack, Headers: amqp.Table{"x-attempt": int32(1)}}
+
+		// attempt 1 -> nextAttempt 2, below MaxAttempts (3): retried, not
+		// parked.
+		if err := topo.Nack(context.Background(), pub, msg, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if fake.published != 1 {
+			t.Fatalf("expected 1 publish, got %d", fake.published)
+		}
+		if got := fake.keys[0]; got != topo.RetryQueueName() {
+			t.Errorf("expected republish to %q, got %q", topo.RetryQueueName(), got)
+		}
+		if got := fake.msgs[0].Headers[AttemptHeader]; got != int32(2) {
+			t.Errorf("expected %s header 2, got %v", AttemptHeader, got)
+		}
+		if got := fake.msgs[0].Expiration; got == "" {
+			t.Error("expected a per-message expiration on the retry republish")
+		}
+		if !ack.acked {
+			t.Error("expected the original delivery to be acked")
+		}
+	})
+
+	t.Run("routes to the DLQ once the next attempt reaches MaxAttempts", func(t *testing.T) {
+		pub, fake := newAckedPublisher()
+		topo := NewTopology(nil, "notifications", TopologyOptions{Retry: retry})
+		ack := &fakeAcknowledger{}
+		msg := amqp.Delivery{Acknowledger: ack}
+
+		// MaxAttempts is 3: attempt 2 -> nextAttempt 3 == MaxAttempts, so this
+		// delivery is parked in the DLQ instead of retried again.
+		if err := topo.Nack(context.Background(), pub, msg, 2); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if fake.published != 1 {
+			t.Fatalf("expected 1 publish, got %d", fake.published)
+		}
+		if got := fake.keys[0]; got != topo.DLQName() {
+			t.Errorf("expected republish to %q, got %q", topo.DLQName(), got)
+		}
+		if got := fake.msgs[0].Headers[AttemptHeader]; got != int32(3) {
+			t.Errorf("expected %s header 3, got %v", AttemptHeader, got)
+		}
+		if got := fake.msgs[0].Expiration; got != "" {
+			t.Errorf("expected no expiration on a DLQ republish, got %q", got)
+		}
+		if !ack.acked {
+			t.Error("expected the original delivery to be acked")
+		}
+	})
+
+	t.Run("preserves existing headers alongside the incremented attempt header", func(t *testing.T) {
+		pub, fake := newAckedPublisher()
+		topo := NewTopology(nil, "notifications", TopologyOptions{Retry: retry})
+		ack := &fakeAcknowledger{}
+		msg := amqp.Delivery{Acknowledger: ack, Headers: amqp.Table{"x-custom": "value"}}
+
+		if err := topo.Nack(context.Background(), pub, msg, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if fake.published != 1 {
+			t.Fatalf("expected 1 publish, got %d", fake.published)
+		}
+		if got := fake.msgs[0].Headers["x-custom"]; got != "value" {
+			t.Errorf("expected x-custom header to be preserved, got %v", got)
+		}
+		if got := fake.msgs[0].Headers[AttemptHeader]; got != int32(2) {
+			t.Errorf("expected %s header 2, got %v", AttemptHeader, got)
+		}
+	})
+}
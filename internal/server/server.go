@@ -0,0 +1,46 @@
+// Package server exposes the HTTP endpoints used for liveness, readiness
+// and Prometheus scraping.
+package server
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Checker reports whether the service is ready to accept work.
+type Checker interface {
+	IsConnected() bool
+	ConsumersHealthy() bool
+}
+
+// New builds an HTTP server exposing /healthz, /readyz and /metrics on
+// addr. Liveness always succeeds once the process is up; readiness depends
+// on checker.
+func New(addr string, checker Checker) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handleHealthz)
+	mux.HandleFunc("/readyz", handleReadyz(checker))
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+}
+
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+func handleReadyz(checker Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !checker.IsConnected() || !checker.ConsumersHealthy() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	}
+}